@@ -0,0 +1,153 @@
+package commands
+
+import (
+	stdcontext "context"
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/Southclaws/cj/reports"
+)
+
+// commandReport handles `!report @user <reason>`, `!report message <id>
+// <reason>` and `!report forum <profile-id> <reason>`.
+func (cm *CommandManager) commandReport(
+	args string,
+	message discordgo.Message,
+	contextual bool,
+) (context bool, err error) {
+	fields := strings.Fields(args)
+	if len(fields) < 2 {
+		cm.Discord.ChannelMessageSend(message.ChannelID, "usage: !report <@user|message <id>|forum <profile-id>> <reason>")
+		return
+	}
+
+	var (
+		subjectType reports.SubjectType
+		subjectID   string
+		reason      string
+		snapshot    *reports.ForumSnapshot
+	)
+
+	switch fields[0] {
+	case "message":
+		if len(fields) < 3 {
+			cm.Discord.ChannelMessageSend(message.ChannelID, "usage: !report message <id> <reason>")
+			return
+		}
+		subjectType = reports.SubjectMessage
+		subjectID = fields[1]
+		reason = strings.Join(fields[2:], " ")
+
+	case "forum":
+		if len(fields) < 3 {
+			cm.Discord.ChannelMessageSend(message.ChannelID, "usage: !report forum <profile-id> <reason>")
+			return
+		}
+		subjectType = reports.SubjectForumPost
+		subjectID = fields[1]
+		reason = strings.Join(fields[2:], " ")
+
+		profile, profileErr := cm.Forum.GetUserProfilePage(stdcontext.Background(), subjectID)
+		if profileErr != nil {
+			cm.Discord.ChannelMessageSend(message.ChannelID, "couldn't fetch that forum profile: "+profileErr.Error())
+			return
+		}
+
+		snapshot = &reports.ForumSnapshot{
+			UserName:   profile.UserName,
+			TotalPosts: profile.TotalPosts,
+			BioText:    profile.BioText,
+		}
+
+	default:
+		subjectType = reports.SubjectUser
+		subjectID = strings.Trim(fields[0], "<@!>")
+		reason = strings.Join(fields[1:], " ")
+	}
+
+	if !cm.Reports.IsValidReason(reason) {
+		cm.Discord.ChannelMessageSend(message.ChannelID, fmt.Sprintf(
+			"reason must be one of: %s", strings.Join(cm.Reports.Reasons(), ", ")))
+		return
+	}
+
+	r, err := cm.Reports.File(message.Author.ID, subjectType, subjectID, reason, snapshot)
+	if err != nil {
+		cm.Discord.ChannelMessageSend(message.ChannelID, "couldn't file that report: "+err.Error())
+		return
+	}
+
+	cm.Discord.ChannelMessageSend(message.ChannelID, fmt.Sprintf("thanks, filed as `%s`", r.ID))
+
+	return
+}
+
+// commandReportsList handles the staff-only `!reports list`.
+func (cm *CommandManager) commandReportsList(
+	args string,
+	message discordgo.Message,
+	contextual bool,
+) (context bool, err error) {
+	if !cm.isStaff(message) {
+		return
+	}
+
+	open, err := cm.Reports.List(reports.StatusOpen)
+	if err != nil {
+		cm.Discord.ChannelMessageSend(message.ChannelID, "couldn't list reports: "+err.Error())
+		return
+	}
+
+	if len(open) == 0 {
+		cm.Discord.ChannelMessageSend(message.ChannelID, "no open reports")
+		return
+	}
+
+	var b strings.Builder
+	for _, r := range open {
+		fmt.Fprintf(&b, "`%s` - %s `%s` - %s\n", r.ID, r.SubjectType, r.SubjectID, r.Reason)
+	}
+
+	cm.Discord.ChannelMessageSend(message.ChannelID, b.String())
+
+	return
+}
+
+// commandReportsResolve handles the staff-only `!reports resolve <id>`.
+func (cm *CommandManager) commandReportsResolve(
+	args string,
+	message discordgo.Message,
+	contextual bool,
+) (context bool, err error) {
+	if !cm.isStaff(message) {
+		return
+	}
+
+	id := strings.TrimSpace(args)
+	if id == "" {
+		cm.Discord.ChannelMessageSend(message.ChannelID, "usage: !reports resolve <id>")
+		return
+	}
+
+	if _, err = cm.Reports.Resolve(id); err != nil {
+		cm.Discord.ChannelMessageSend(message.ChannelID, "no such report: "+id)
+		return false, nil
+	}
+
+	cm.Discord.ChannelMessageSend(message.ChannelID, "resolved `"+id+"`")
+
+	return
+}
+
+// isStaff reports whether message's author has administrator permissions
+// in the channel it was sent in.
+func (cm *CommandManager) isStaff(message discordgo.Message) bool {
+	perms, err := cm.Discord.State.UserChannelPermissions(message.Author.ID, message.ChannelID)
+	if err != nil {
+		return false
+	}
+
+	return perms&discordgo.PermissionAdministrator != 0
+}
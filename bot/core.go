@@ -1,15 +1,22 @@
 package bot
 
 import (
+	"context"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/pkg/errors"
 	"go.uber.org/zap"
 
 	"github.com/Southclaws/cj/bot/commands"
+	"github.com/Southclaws/cj/extension"
 	"github.com/Southclaws/cj/forum"
+	"github.com/Southclaws/cj/forum/discourse"
+	"github.com/Southclaws/cj/forum/vbulletin"
+	"github.com/Southclaws/cj/reports"
 	"github.com/Southclaws/cj/storage"
 	"github.com/Southclaws/cj/types"
 )
@@ -21,56 +28,145 @@ type App struct {
 	storage       *storage.API
 	forum         *forum.ForumClient
 	ready         chan bool
-	extensions    []Extension
+	extensions    []extension.Extension
 }
 
 // Extension represents an extension to the bot that receives a pointer to the
 // storage backend.
-type Extension interface {
-	Init(*types.Config, *discordgo.Session, *storage.API, *forum.ForumClient) error
-	OnMessage(discordgo.Message) error
-}
+//
+// Deprecated: use extension.Extension, this alias exists so existing code
+// that refers to bot.Extension keeps compiling.
+type Extension = extension.Extension
+
+// shutdownTimeout bounds how long Start waits for extensions to shut down
+// cleanly once the root context is cancelled.
+const shutdownTimeout = time.Second * 10
 
-// Start starts the app with the specified config and blocks until fatal error
+// Start starts the app with the specified config and blocks until the
+// process receives SIGINT or SIGTERM, then shuts down gracefully.
 func Start(config *types.Config) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
 	app := App{
 		config: config,
 	}
 
 	var err error
 
-	app.forum, err = forum.NewForumClient()
+	app.storage, err = storage.New(config.StoragePath)
+	if err != nil {
+		logger.Fatal("failed to initialise storage", zap.Error(err))
+	}
+
+	backend, err := newForumBackend(config)
+	if err != nil {
+		logger.Fatal("failed to initialise forum backend", zap.Error(err))
+	}
+
+	app.forum, err = forum.NewForumClient(backend)
 	if err != nil {
 		logger.Fatal("failed to initialise forum client", zap.Error(err))
 	}
+	app.forum.WithStorage(app.storage)
+	app.forum.WithPollInterval(config.Forum.PollInterval, config.Forum.PollJitter)
 
 	err = app.ConnectDiscord()
 	if err != nil {
 		logger.Fatal("failed to connect to discord", zap.Error(err))
 	}
 
-	app.extensions = []Extension{
-		&commands.CommandManager{},
+	// reportsExt is wired into CommandManager directly (rather than
+	// discovered through OnMessage) so the `!report`/`!reports` commands
+	// can call File/List/Resolve synchronously.
+	reportsExt := &reports.Extension{}
+
+	app.extensions = []extension.Extension{
+		reportsExt,
+		&commands.CommandManager{Reports: reportsExt},
+	}
+
+	for _, path := range config.Plugins {
+		ex, err := LoadPlugin(path)
+		if err != nil {
+			logger.Fatal("failed to load plugin", zap.String("path", path), zap.Error(err))
+		}
+		app.extensions = append(app.extensions, ex)
 	}
 
 	for _, ex := range app.extensions {
-		err = ex.Init(config, app.discordClient, app.storage, app.forum)
+		err = ex.Init(ctx, config, app.discordClient, app.storage, app.forum)
 		if err != nil {
 			logger.Fatal("failed to initialise extension", zap.Error(err))
 		}
 	}
 
-	app.forum.NewPostAlert("3", func() {
-		app.discordClient.ChannelMessageSend(
-			config.PrimaryChannel,
-			"New Kalcor Post: http://forum.sa-mp.com/search.php?do=finduser&u=3",
-		)
-	})
+	app.forum.Watch(ctx, "3")
+	kalcorPosts, _ := app.forum.Subscribe(forum.EventFilter{UserIDs: []string{"3"}})
+	go func() {
+		for range kalcorPosts {
+			app.discordClient.ChannelMessageSend(
+				config.PrimaryChannel,
+				"New Kalcor Post: http://forum.sa-mp.com/search.php?do=finduser&u=3",
+			)
+		}
+	}()
 
 	logger.Debug("started with debug logging enabled",
 		zap.Any("config", config))
 
+	// SIGKILL can't be caught, so there's no point listening for it; SIGINT
+	// covers Ctrl-C during local development and SIGTERM covers
+	// systemd/docker stop.
 	signals := make(chan os.Signal, 1)
-	signal.Notify(signals, syscall.SIGTERM, syscall.SIGKILL)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
 	<-signals
+
+	cancel()
+	app.shutdown()
+}
+
+// shutdown gives every extension a bounded deadline to flush in-flight
+// work, then waits for the forum poller to finish its current pass - so it
+// isn't still writing to storage once storage is closed - before closing
+// the discord session and storage backend.
+func (app *App) shutdown() {
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer shutdownCancel()
+
+	for _, ex := range app.extensions {
+		if err := ex.Shutdown(shutdownCtx); err != nil {
+			logger.Error("extension failed to shut down cleanly", zap.Error(err))
+		}
+	}
+
+	if app.forum != nil {
+		if err := app.forum.Shutdown(shutdownCtx); err != nil {
+			logger.Error("forum poller failed to shut down cleanly", zap.Error(err))
+		}
+	}
+
+	if err := app.discordClient.Close(); err != nil {
+		logger.Error("failed to close discord session", zap.Error(err))
+	}
+
+	if app.storage != nil {
+		if err := app.storage.Close(); err != nil {
+			logger.Error("failed to flush storage", zap.Error(err))
+		}
+	}
+}
+
+// newForumBackend picks and constructs a forum.Backend based on
+// config.Forum.Kind, defaulting to the original vBulletin scraper so
+// existing deployments don't need to change their config.
+func newForumBackend(config *types.Config) (forum.Backend, error) {
+	switch config.Forum.Kind {
+	case "discourse":
+		return discourse.New(config.Forum.BaseURL), nil
+	case "vbulletin", "":
+		return vbulletin.New()
+	default:
+		return nil, errors.Errorf("unknown forum backend kind: %q", config.Forum.Kind)
+	}
 }
@@ -0,0 +1,37 @@
+package bot
+
+import (
+	"plugin"
+
+	"github.com/pkg/errors"
+
+	"github.com/Southclaws/cj/extension"
+)
+
+// pluginSymbol is the exported name plugin `.so` files must provide: a
+// factory function that returns a new Extension instance.
+const pluginSymbol = "Extension"
+
+// LoadPlugin opens a Go plugin built with `-buildmode=plugin` and looks up
+// its exported `Extension` symbol, a `func() extension.Extension` factory,
+// to construct a new Extension. This allows operators to ship custom
+// commands or alerts without forking the bot, by listing the built `.so`
+// paths in `types.Config.Plugins`.
+func LoadPlugin(path string) (extension.Extension, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open plugin")
+	}
+
+	sym, err := p.Lookup(pluginSymbol)
+	if err != nil {
+		return nil, errors.Wrap(err, "plugin does not export an Extension symbol")
+	}
+
+	factory, ok := sym.(func() extension.Extension)
+	if !ok {
+		return nil, errors.New("plugin Extension symbol has the wrong signature")
+	}
+
+	return factory(), nil
+}
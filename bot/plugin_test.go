@@ -0,0 +1,48 @@
+package bot
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var dummyPluginPath string
+
+// TestMain builds the dummy Extension plugin under testdata/dummyplugin
+// before running the package's tests, so TestLoadPlugin can exercise a real
+// `.so` file rather than a mock.
+func TestMain(m *testing.M) {
+	dir, err := ioutil.TempDir("", "cj-plugin-test")
+	if err != nil {
+		panic(err)
+	}
+
+	dummyPluginPath = filepath.Join(dir, "dummy.so")
+
+	cmd := exec.Command("go", "build", "-buildmode=plugin", "-o", dummyPluginPath, "./testdata/dummyplugin")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		panic("failed to build dummy plugin: " + err.Error() + "\n" + string(out))
+	}
+
+	// os.Exit doesn't run deferred functions, so the cleanup has to happen
+	// between m.Run() and os.Exit rather than via defer.
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func TestLoadPlugin(t *testing.T) {
+	ex, err := LoadPlugin(dummyPluginPath)
+	require.NoError(t, err)
+	assert.NotNil(t, ex)
+}
+
+func TestLoadPlugin_NotFound(t *testing.T) {
+	_, err := LoadPlugin(filepath.Join(os.TempDir(), "does-not-exist.so"))
+	assert.Error(t, err)
+}
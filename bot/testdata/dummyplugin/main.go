@@ -0,0 +1,34 @@
+// Package main builds a dummy Extension used by bot's plugin_test.go to
+// exercise bot.LoadPlugin against a real `-buildmode=plugin` `.so` file.
+package main
+
+import (
+	"context"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/Southclaws/cj/extension"
+	"github.com/Southclaws/cj/forum"
+	"github.com/Southclaws/cj/storage"
+	"github.com/Southclaws/cj/types"
+)
+
+// dummyExtension is a no-op Extension.
+type dummyExtension struct{}
+
+func (d *dummyExtension) Init(context.Context, *types.Config, *discordgo.Session, *storage.API, *forum.ForumClient) error {
+	return nil
+}
+
+func (d *dummyExtension) OnMessage(discordgo.Message) error {
+	return nil
+}
+
+func (d *dummyExtension) Shutdown(context.Context) error {
+	return nil
+}
+
+// Extension is the factory symbol bot.LoadPlugin looks up.
+func Extension() extension.Extension {
+	return &dummyExtension{}
+}
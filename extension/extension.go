@@ -0,0 +1,25 @@
+// Package extension defines the contract bot extensions implement. It lives
+// outside package bot so both built-in extensions (commands.CommandManager)
+// and third-party Go plugins can depend on it without pulling in bot itself
+// and creating an import cycle.
+package extension
+
+import (
+	"context"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/Southclaws/cj/forum"
+	"github.com/Southclaws/cj/storage"
+	"github.com/Southclaws/cj/types"
+)
+
+// Extension represents an extension to the bot that receives a pointer to
+// the storage backend. ctx is the app's root context: it's cancelled when
+// the process receives SIGINT/SIGTERM, at which point Shutdown is called
+// with a bounded deadline so the extension can flush in-flight work.
+type Extension interface {
+	Init(ctx context.Context, config *types.Config, discord *discordgo.Session, store *storage.API, forum *forum.ForumClient) error
+	OnMessage(discordgo.Message) error
+	Shutdown(ctx context.Context) error
+}
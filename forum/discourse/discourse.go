@@ -0,0 +1,126 @@
+// Package discourse implements forum.Backend against a Discourse
+// instance's JSON API, for communities moving off SA:MP's vBulletin forum.
+package discourse
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/Southclaws/cj/forum"
+)
+
+// Backend implements forum.Backend against a Discourse instance's JSON API
+// rather than scraping HTML.
+type Backend struct {
+	httpClient *http.Client
+	baseURL    string
+}
+
+// New creates a Discourse-backed forum.Backend rooted at baseURL, e.g.
+// "https://forum.example.com".
+func New(baseURL string) *Backend {
+	return &Backend{
+		httpClient: http.DefaultClient,
+		baseURL:    strings.TrimRight(baseURL, "/"),
+	}
+}
+
+// userResponse is the shape of GET /users/{username}.json.
+type userResponse struct {
+	User struct {
+		Username   string `json:"username"`
+		CreatedAt  string `json:"created_at"`
+		PostCount  int    `json:"post_count"`
+		BioRaw     string `json:"bio_raw"`
+		TrustLevel int    `json:"trust_level"`
+	} `json:"user"`
+}
+
+// UserProfile implements forum.Backend.
+func (b *Backend) UserProfile(ctx context.Context, id string) (forum.UserProfile, error) {
+	var resp userResponse
+
+	endpoint := fmt.Sprintf("%s/users/%s.json", b.baseURL, url.PathEscape(id))
+	if err := b.getJSON(ctx, endpoint, &resp); err != nil {
+		return forum.UserProfile{}, errors.Wrap(err, "failed to get user")
+	}
+
+	return forum.UserProfile{
+		UserName:   resp.User.Username,
+		JoinDate:   resp.User.CreatedAt,
+		TotalPosts: resp.User.PostCount,
+		Reputation: resp.User.TrustLevel,
+		BioText:    resp.User.BioRaw,
+		Source:     "discourse",
+	}, nil
+}
+
+// LatestPostCount implements forum.Backend.
+func (b *Backend) LatestPostCount(ctx context.Context, id string) (int, error) {
+	profile, err := b.UserProfile(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+
+	return profile.TotalPosts, nil
+}
+
+// searchResponse is the shape of GET /search.json?q=....
+type searchResponse struct {
+	Posts []struct {
+		TopicTitle string `json:"topic_title"`
+		TopicID    int    `json:"topic_id"`
+	} `json:"posts"`
+}
+
+// Search implements forum.Backend.
+func (b *Backend) Search(ctx context.Context, query string) ([]forum.SearchResult, error) {
+	var resp searchResponse
+
+	endpoint := fmt.Sprintf("%s/search.json?q=%s", b.baseURL, url.QueryEscape(query))
+	if err := b.getJSON(ctx, endpoint, &resp); err != nil {
+		return nil, errors.Wrap(err, "failed to search")
+	}
+
+	results := make([]forum.SearchResult, 0, len(resp.Posts))
+	for _, post := range resp.Posts {
+		results = append(results, forum.SearchResult{
+			Title: post.TopicTitle,
+			URL:   fmt.Sprintf("%s/t/%d", b.baseURL, post.TopicID),
+		})
+	}
+
+	return results, nil
+}
+
+// getJSON performs an HTTP GET against endpoint and decodes the JSON
+// response body into v.
+func (b *Backend) getJSON(ctx context.Context, endpoint string, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to build request")
+	}
+	req = req.WithContext(ctx)
+
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform request")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return errors.Wrap(err, "failed to decode response")
+	}
+
+	return nil
+}
@@ -0,0 +1,328 @@
+package forum
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// defaultPollInterval is the base delay between poller passes used when
+// WithPollInterval hasn't set one; a random amount of jitter
+// (defaultPollJitter) is added so many deployments polling the same forum
+// don't all land on it at once.
+const (
+	defaultPollInterval = time.Second * 10
+	defaultPollJitter   = time.Second * 3
+)
+
+// Event is implemented by every event the forum poller can emit.
+type Event interface{ forumEvent() }
+
+// NewPostEvent fires when a watched user's total post count increases.
+type NewPostEvent struct {
+	UserID    string
+	PostCount int
+	Delta     int
+	At        time.Time
+}
+
+func (NewPostEvent) forumEvent() {}
+
+// EditEvent fires when a watched user edits an existing post. No Backend
+// currently reports edits, so nothing publishes this yet; it exists so
+// subscribers can be written against the full event set up front.
+type EditEvent struct {
+	UserID string
+	PostID string
+}
+
+func (EditEvent) forumEvent() {}
+
+// VisitorMessageEvent describes a new visitor message on a watched user's
+// profile. pollOnce only compares post counts, so the poller does not
+// publish this event; a new visitor message alone produces no event.
+type VisitorMessageEvent struct {
+	UserID string
+	From   string
+	Body   string
+}
+
+func (VisitorMessageEvent) forumEvent() {}
+
+// EventFilter narrows a Subscribe call. A zero-value EventFilter matches
+// every event for every watched user.
+type EventFilter struct {
+	// UserIDs restricts delivery to these users. Empty means all users.
+	UserIDs []string
+	// Match, if set, is an additional predicate (typically a type switch
+	// over Event) an event must satisfy to be delivered.
+	Match func(Event) bool
+}
+
+func (f EventFilter) matches(e Event) bool {
+	if len(f.UserIDs) > 0 {
+		id, ok := eventUserID(e)
+		if !ok {
+			return false
+		}
+
+		found := false
+		for _, want := range f.UserIDs {
+			if want == id {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if f.Match != nil && !f.Match(e) {
+		return false
+	}
+
+	return true
+}
+
+func eventUserID(e Event) (string, bool) {
+	switch v := e.(type) {
+	case NewPostEvent:
+		return v.UserID, true
+	case EditEvent:
+		return v.UserID, true
+	case VisitorMessageEvent:
+		return v.UserID, true
+	}
+	return "", false
+}
+
+// subscription pairs a filter with the channel events matching it are
+// delivered to.
+type subscription struct {
+	filter EventFilter
+	ch     chan Event
+}
+
+// bus fans out Events to interested Subscribe callers.
+type bus struct {
+	mu   sync.Mutex
+	subs map[int]*subscription
+	next int
+}
+
+func newBus() *bus {
+	return &bus{subs: make(map[int]*subscription)}
+}
+
+func (b *bus) subscribe(filter EventFilter) (<-chan Event, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.next
+	b.next++
+
+	ch := make(chan Event, 16)
+	b.subs[id] = &subscription{filter: filter, ch: ch}
+
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		if sub, ok := b.subs[id]; ok {
+			delete(b.subs, id)
+			close(sub.ch)
+		}
+	}
+}
+
+func (b *bus) publish(e Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, sub := range b.subs {
+		if !sub.filter.matches(e) {
+			continue
+		}
+
+		select {
+		case sub.ch <- e:
+		default:
+			// Subscriber isn't keeping up; drop rather than block the
+			// poller.
+		}
+	}
+}
+
+// Watch adds id to the set of users the poller refreshes every tick. It is
+// safe to call Watch for the same id more than once. ctx governs the
+// poller's lifetime: once ctx is done, the poller stops after its current
+// pass.
+func (fc *ForumClient) Watch(ctx context.Context, id string) {
+	fc.mu.Lock()
+	if fc.watched == nil {
+		fc.watched = make(map[string]bool)
+	}
+	fc.watched[id] = true
+	fc.mu.Unlock()
+
+	fc.ensurePoller(ctx)
+}
+
+// Subscribe returns a channel of Events matching filter and an unsubscribe
+// function. Extensions call this (via the ForumClient handed to
+// Extension.Init) instead of running their own polling loop.
+func (fc *ForumClient) Subscribe(filter EventFilter) (<-chan Event, func()) {
+	fc.mu.Lock()
+	if fc.bus == nil {
+		fc.bus = newBus()
+	}
+	b := fc.bus
+	fc.mu.Unlock()
+
+	return b.subscribe(filter)
+}
+
+// ensurePoller starts the single background poller on first use. Every
+// tick it batches all Watch'd users into one pass, diffing each against
+// the last count pollOnce itself observed (and, on the first tick after a
+// restart, against the post count persisted via storage.API) to decide
+// whether to publish a NewPostEvent.
+func (fc *ForumClient) ensurePoller(ctx context.Context) {
+	fc.mu.Lock()
+	if fc.pollerStarted {
+		fc.mu.Unlock()
+		return
+	}
+	fc.pollerStarted = true
+	if fc.store == nil {
+		fc.store = NewStore(fc.GetUserProfilePage, fc.storage)
+	}
+	if fc.bus == nil {
+		fc.bus = newBus()
+	}
+	fc.mu.Unlock()
+
+	interval := fc.pollInterval
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+
+	jitter := fc.pollJitter
+	if jitter <= 0 {
+		jitter = defaultPollJitter
+	}
+
+	fc.pollerWG.Add(1)
+	go func() {
+		defer fc.pollerWG.Done()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(interval + time.Duration(rand.Int63n(int64(jitter)))):
+				fc.pollOnce(ctx)
+			}
+		}
+	}()
+}
+
+// pollOnce checks every watched user with the backend's cheap
+// LatestPostCount (a single GET, skipping the reputation/bio/visitor
+// message requests UserProfile makes) and only pays for a full profile
+// refresh - via Store.Load - when that count has actually gone up, or when
+// there is no previous count for the user to compare against.
+func (fc *ForumClient) pollOnce(ctx context.Context) {
+	fc.mu.Lock()
+	ids := make([]string, 0, len(fc.watched))
+	for id := range fc.watched {
+		ids = append(ids, id)
+	}
+	fc.mu.Unlock()
+
+	for _, id := range ids {
+		before, hadPrevious := fc.lastKnownPostCount(id)
+
+		count, err := fc.backend.LatestPostCount(ctx, id)
+		if err != nil {
+			continue
+		}
+
+		if hadPrevious && count <= before {
+			continue
+		}
+
+		if err := fc.store.Load(ctx, id); err != nil {
+			continue
+		}
+
+		after, ok := fc.store.Get(id)
+		if !ok {
+			continue
+		}
+
+		if hadPrevious && after.TotalPosts > before {
+			fc.bus.publish(NewPostEvent{
+				UserID:    id,
+				PostCount: after.TotalPosts,
+				Delta:     after.TotalPosts - before,
+				At:        time.Now(),
+			})
+		}
+
+		fc.saveLastPostCount(id, after.TotalPosts)
+	}
+}
+
+// lastKnownPostCount returns the post count ForumClient last saw for id,
+// preferring the in-memory postCounts baseline (set by a previous pollOnce
+// pass and never expired) and falling back to the count persisted via
+// storage.API so a restart doesn't treat a user's first tick as a brand
+// new post.
+func (fc *ForumClient) lastKnownPostCount(id string) (int, bool) {
+	fc.mu.Lock()
+	count, ok := fc.postCounts[id]
+	fc.mu.Unlock()
+
+	if ok {
+		return count, true
+	}
+
+	return fc.loadLastPostCount(id)
+}
+
+// pollerPersisted is the shape persisted per watched user so a restart
+// doesn't treat their first tick as a brand new post.
+type pollerPersisted struct {
+	LastPostCount int `json:"last_post_count"`
+}
+
+func (fc *ForumClient) loadLastPostCount(id string) (int, bool) {
+	if fc.storage == nil {
+		return 0, false
+	}
+
+	var state pollerPersisted
+	if err := fc.storage.Get("forum_poller", id, &state); err != nil {
+		return 0, false
+	}
+
+	return state.LastPostCount, true
+}
+
+func (fc *ForumClient) saveLastPostCount(id string, count int) {
+	fc.mu.Lock()
+	if fc.postCounts == nil {
+		fc.postCounts = make(map[string]int)
+	}
+	fc.postCounts[id] = count
+	fc.mu.Unlock()
+
+	if fc.storage == nil {
+		return
+	}
+
+	_ = fc.storage.Set("forum_poller", id, pollerPersisted{LastPostCount: count})
+}
@@ -0,0 +1,206 @@
+package forum
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventFilter_Matches(t *testing.T) {
+	t.Run("zero value matches everything", func(t *testing.T) {
+		var f EventFilter
+		assert.True(t, f.matches(NewPostEvent{UserID: "1"}))
+	})
+
+	t.Run("UserIDs restricts by user", func(t *testing.T) {
+		f := EventFilter{UserIDs: []string{"1"}}
+		assert.True(t, f.matches(NewPostEvent{UserID: "1"}))
+		assert.False(t, f.matches(NewPostEvent{UserID: "2"}))
+	})
+
+	t.Run("Match is an additional predicate", func(t *testing.T) {
+		f := EventFilter{Match: func(e Event) bool {
+			_, ok := e.(VisitorMessageEvent)
+			return ok
+		}}
+		assert.True(t, f.matches(VisitorMessageEvent{UserID: "1"}))
+		assert.False(t, f.matches(NewPostEvent{UserID: "1"}))
+	})
+}
+
+func TestBus_SubscribePublish(t *testing.T) {
+	b := newBus()
+
+	ch, unsubscribe := b.subscribe(EventFilter{UserIDs: []string{"1"}})
+	defer unsubscribe()
+
+	b.publish(NewPostEvent{UserID: "2"})
+	b.publish(NewPostEvent{UserID: "1", PostCount: 5})
+
+	select {
+	case e := <-ch:
+		evt, ok := e.(NewPostEvent)
+		require.True(t, ok)
+		assert.Equal(t, "1", evt.UserID)
+		assert.Equal(t, 5, evt.PostCount)
+	case <-time.After(time.Second):
+		t.Fatal("expected a matching event to be delivered")
+	}
+}
+
+func TestBus_Unsubscribe(t *testing.T) {
+	b := newBus()
+
+	ch, unsubscribe := b.subscribe(EventFilter{})
+	unsubscribe()
+
+	_, ok := <-ch
+	assert.False(t, ok, "channel should be closed after unsubscribe")
+}
+
+// fakeBackend is a forum.Backend whose LatestPostCount and UserProfile
+// results are driven by test cases, so pollOnce's diffing logic can be
+// exercised without any HTTP calls.
+type fakeBackend struct {
+	latestPostCount int
+	latestErr       error
+	profile         UserProfile
+	profileErr      error
+}
+
+func (f *fakeBackend) UserProfile(ctx context.Context, id string) (UserProfile, error) {
+	return f.profile, f.profileErr
+}
+
+func (f *fakeBackend) LatestPostCount(ctx context.Context, id string) (int, error) {
+	return f.latestPostCount, f.latestErr
+}
+
+func (f *fakeBackend) Search(ctx context.Context, query string) ([]SearchResult, error) {
+	return nil, nil
+}
+
+func TestForumClient_PollOnce_PublishesOnIncrease(t *testing.T) {
+	backend := &fakeBackend{
+		latestPostCount: 11,
+		profile:         UserProfile{TotalPosts: 11},
+	}
+
+	fc, err := NewForumClient(backend)
+	require.NoError(t, err)
+	fc.store = NewStore(fc.GetUserProfilePage, nil)
+	fc.bus = newBus()
+	fc.watched = map[string]bool{"1": true}
+
+	// Seed the poller's diffing baseline with the previously seen post count.
+	fc.saveLastPostCount("1", 10)
+
+	ch, unsubscribe := fc.Subscribe(EventFilter{})
+	defer unsubscribe()
+
+	fc.pollOnce(context.Background())
+
+	select {
+	case e := <-ch:
+		evt, ok := e.(NewPostEvent)
+		require.True(t, ok)
+		assert.Equal(t, "1", evt.UserID)
+		assert.Equal(t, 11, evt.PostCount)
+		assert.Equal(t, 1, evt.Delta)
+	case <-time.After(time.Second):
+		t.Fatal("expected a NewPostEvent to be published")
+	}
+}
+
+// TestForumClient_PollOnce_SurvivesStaleProfileCache guards against a
+// regression where the diffing baseline was read from the profile Store
+// (which expires after profileTTL) instead of a dedicated baseline that
+// persists for the poller's lifetime: an infrequent poster whose cached
+// profile has expired between ticks must still produce a NewPostEvent the
+// next time they post, not a silent re-baseline.
+func TestForumClient_PollOnce_SurvivesStaleProfileCache(t *testing.T) {
+	backend := &fakeBackend{
+		latestPostCount: 11,
+		profile:         UserProfile{TotalPosts: 11},
+	}
+
+	fc, err := NewForumClient(backend)
+	require.NoError(t, err)
+	fc.store = NewStore(fc.GetUserProfilePage, nil)
+	fc.bus = newBus()
+	fc.watched = map[string]bool{"1": true}
+
+	fc.saveLastPostCount("1", 10)
+
+	// Simulate the profile cache (but not the poller's own baseline) having
+	// expired since the last tick.
+	_, stillCached := fc.store.Get("1")
+	assert.False(t, stillCached, "test setup: store should start without a cached profile")
+
+	ch, unsubscribe := fc.Subscribe(EventFilter{})
+	defer unsubscribe()
+
+	fc.pollOnce(context.Background())
+
+	select {
+	case e := <-ch:
+		evt, ok := e.(NewPostEvent)
+		require.True(t, ok)
+		assert.Equal(t, 11, evt.PostCount)
+		assert.Equal(t, 1, evt.Delta)
+	case <-time.After(time.Second):
+		t.Fatal("expected a NewPostEvent even though the profile cache had expired")
+	}
+}
+
+func TestForumClient_PollOnce_NoChangeNoEvent(t *testing.T) {
+	backend := &fakeBackend{
+		latestPostCount: 10,
+		profile:         UserProfile{TotalPosts: 10},
+	}
+
+	fc, err := NewForumClient(backend)
+	require.NoError(t, err)
+	fc.store = NewStore(fc.GetUserProfilePage, nil)
+	fc.bus = newBus()
+	fc.watched = map[string]bool{"1": true}
+
+	fc.saveLastPostCount("1", 10)
+
+	ch, unsubscribe := fc.Subscribe(EventFilter{})
+	defer unsubscribe()
+
+	fc.pollOnce(context.Background())
+
+	select {
+	case e := <-ch:
+		t.Fatalf("expected no event, got %#v", e)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestForumClient_PollOnce_SkipsFullFetchWhenCountUnchanged(t *testing.T) {
+	fetchCalls := 0
+	backend := &fakeBackend{
+		latestPostCount: 10,
+		profile:         UserProfile{TotalPosts: 10},
+	}
+
+	fc, err := NewForumClient(backend)
+	require.NoError(t, err)
+	fc.store = NewStore(func(ctx context.Context, id string) (UserProfile, error) {
+		fetchCalls++
+		return backend.UserProfile(ctx, id)
+	}, nil)
+	fc.bus = newBus()
+	fc.watched = map[string]bool{"1": true}
+
+	fc.saveLastPostCount("1", 10)
+
+	fc.pollOnce(context.Background())
+
+	assert.Equal(t, 0, fetchCalls, "pollOnce should not fetch the full profile when LatestPostCount hasn't changed")
+}
@@ -1,20 +1,37 @@
 package forum
 
 import (
-	"fmt"
-	"net/http"
-	"strconv"
-	"strings"
+	"context"
+	"sync"
 	"time"
 
-	"github.com/Southclaws/go-cloudflare-scraper"
-	"github.com/pkg/errors"
-	"gopkg.in/xmlpath.v2"
+	"github.com/Southclaws/cj/storage"
 )
 
-// ForumClient provides programatic access to SA:MP forum
+// ForumClient provides cached, backend-agnostic access to a forum. The
+// actual scraping or API calls are delegated to a Backend (see
+// forum/vbulletin and forum/discourse); ForumClient layers caching
+// (Store), persistence (storage.API) and a polling event bus (see
+// event.go) on top.
 type ForumClient struct {
-	httpClient *http.Client
+	backend Backend
+	store   Store
+	storage *storage.API
+
+	mu            sync.Mutex
+	watched       map[string]bool
+	pollerStarted bool
+	bus           *bus
+	pollInterval  time.Duration
+	pollJitter    time.Duration
+	pollerWG      sync.WaitGroup
+
+	// postCounts is the poller's diffing baseline: the last post count
+	// pollOnce actually observed for each watched user. It's kept separate
+	// from store (which evicts/expires entries independently) so a post
+	// isn't missed just because that user's profile cache went stale
+	// between ticks.
+	postCounts map[string]int
 }
 
 // UserProfile stores data available on a user's profile page
@@ -25,7 +42,11 @@ type UserProfile struct {
 	Reputation      int
 	BioText         string
 	VisitorMessages []VisitorMessage
-	Errors          []error
+	// Source names the Backend that produced this profile, e.g.
+	// "vbulletin" or "discourse", so extensions can tell where data came
+	// from.
+	Source string
+	Errors []error
 }
 
 // VisitorMessage represents a single visitor message available on a user page.
@@ -34,221 +55,79 @@ type VisitorMessage struct {
 	Message  string
 }
 
-// NewForumClient creates a new forum clienta
-func NewForumClient() (fc *ForumClient, err error) {
-	fc = new(ForumClient)
-	scrpr, err := scraper.NewTransport(http.DefaultTransport)
-	if err != nil {
-		return
-	}
-	fc.httpClient = &http.Client{Transport: scrpr}
-	return
+// SearchResult is a single hit returned by Backend.Search.
+type SearchResult struct {
+	Title string
+	URL   string
 }
 
-// GetUserProfilePage does a HTTP GET on the user's profile page then extracts
-// structured information from it.
-func (fc *ForumClient) GetUserProfilePage(url string) (UserProfile, error) {
-	var result UserProfile
-
-	root, err := fc.GetHTMLRoot(url)
-	if err != nil {
-		return result, errors.Wrap(err, "failed to get HTML root for user page")
-	}
-
-	result.UserName, err = fc.getUserName(root)
-	if err != nil {
-		return result, errors.Wrap(err, "url did not lead to a valid user page")
-	}
-
-	result.JoinDate, err = fc.getJoinDate(root)
-	if err != nil {
-		result.Errors = append(result.Errors, err)
-	}
-
-	result.TotalPosts, err = fc.getTotalPosts(root)
-	if err != nil {
-		result.Errors = append(result.Errors, err)
-	}
-
-	result.Reputation, err = fc.getReputation(strings.TrimPrefix(url, "http://forum.sa-mp.com/member.php?u="))
-	if err != nil {
-		result.Errors = append(result.Errors, err)
-	}
-
-	result.BioText, err = fc.getUserBio(root)
-	if err != nil {
-		result.Errors = append(result.Errors, err)
-	}
-
-	result.VisitorMessages, err = fc.getFirstTenUserVisitorMessages(root)
-	if err != nil {
-		result.Errors = append(result.Errors, err)
-	}
-
-	return result, nil
+// Backend abstracts over a specific forum software's scraping or API
+// implementation, so ForumClient isn't hard-wired to SA:MP's vBulletin
+// HTML. Concrete backends live in forum/vbulletin and forum/discourse;
+// App picks one at startup based on types.Config.Forum.Kind.
+type Backend interface {
+	UserProfile(ctx context.Context, id string) (UserProfile, error)
+	LatestPostCount(ctx context.Context, id string) (int, error)
+	Search(ctx context.Context, query string) ([]SearchResult, error)
 }
 
-// getUserName returns the user profile page owner name
-func (fc *ForumClient) getUserName(root *xmlpath.Node) (string, error) {
-	var result string
-
-	path := xmlpath.MustCompile(`//*[@id="username_box"]/h1`)
-
-	result, ok := path.String(root)
-	if !ok {
-		return result, errors.New("user name xmlpath did not return a result")
-	}
-
-	return strings.Trim(result, "\n "), nil
+// NewForumClient creates a new ForumClient that delegates to backend.
+func NewForumClient(backend Backend) (fc *ForumClient, err error) {
+	fc = &ForumClient{backend: backend}
+	return fc, nil
 }
 
-// getJoinDate returns the user join date
-func (fc *ForumClient) getJoinDate(root *xmlpath.Node) (string, error) {
-	var path *xmlpath.Path
-	var result string
-
-	path = xmlpath.MustCompile(`//*[@id="collapseobj_stats"]/div/*/ul/*[contains(.,'Join Date: ')]`)
-
-	result, ok := path.String(root)
-	if !ok {
-		return result, errors.New("join date xmlpath did not return a result")
-	}
-
-	return strings.TrimPrefix(result, "Join Date: "), nil
+// GetUserProfilePage fetches id's profile through the configured backend.
+// ctx is passed straight through to the backend so an in-flight scrape can
+// be cancelled on shutdown.
+func (fc *ForumClient) GetUserProfilePage(ctx context.Context, id string) (UserProfile, error) {
+	return fc.backend.UserProfile(ctx, id)
 }
 
-// getTotalPosts returns the user total posts
-func (fc *ForumClient) getTotalPosts(root *xmlpath.Node) (int, error) {
-	path := xmlpath.MustCompile(`//*[@id="collapseobj_stats"]/div/fieldset[1]/ul/li[1]`)
-
-	posts, ok := path.String(root)
-	if !ok {
-		return 0, errors.New("total posts xmlpath did not return a result")
-	}
-
-	posts = strings.TrimPrefix(posts, "Total Posts: ")
-	posts = strings.Replace(posts, ",", "", -1)
-
-	result, err := strconv.Atoi(posts)
-	if err != nil {
-		return 0, errors.New("cannot convert posts to integer")
-	}
-
-	return result, nil
+// WithStore attaches s as the Store the poller refreshes watched users'
+// profiles into (see pollOnce). GetUserProfilePage does not read from it and
+// always calls the backend. Returns fc to allow chaining from
+// NewForumClient.
+func (fc *ForumClient) WithStore(s Store) *ForumClient {
+	fc.store = s
+	return fc
 }
 
-// getTotalPosts returns the user total posts
-func (fc *ForumClient) getReputation(forumUserID string) (int, error) {
-	root, err := fc.GetHTMLRoot(fmt.Sprintf("http://forum.sa-mp.com/search.php?do=finduser&u=%s", forumUserID))
-	if err != nil {
-		return 0, errors.Wrap(err, "cannot get user's posts")
-	}
-
-	path := xmlpath.MustCompile(`//td[@class="alt1"]/div[@class="alt2"]/div/em/a/@href`)
-
-	// Get the first post from the list.
-	href, ok := path.String(root)
-	if !ok {
-		return 0, errors.New("cannot get user posts")
-	}
-
-	// If we have a valid post, search in it for user's reputation.
-	root, err = fc.GetHTMLRoot(fmt.Sprintf("http://forum.sa-mp.com/%s", href))
-	if err != nil {
-		return 0, errors.Wrap(err, "cannot get user's post in a topic")
-	}
-
-	path = xmlpath.MustCompile(fmt.Sprintf(
-		`//table[@id="%s"]/tbody/tr[@valign="top"]/td[@class="alt2"]/*/*[contains(text(),'Reputation: ')]`,
-		strings.Split(href, "#")[1],
-	))
-
-	fields := path.Iter(root)
-	var reputation string
-	for fields.Next() {
-		reputation = fields.Node().String()
-	}
-
-	// Get the table for that post.
-
-	reputation = strings.TrimPrefix(reputation, "Reputation: ")
-	reputation = strings.Replace(reputation, ",", "", -1)
-
-	result, err := strconv.Atoi(reputation)
-	if err != nil {
-		return 0, errors.Wrap(err, "cannot convert reputation to integer")
-	}
-
-	return result, nil
-}
-
-// getUserBio returns the bio text.
-func (fc *ForumClient) getUserBio(root *xmlpath.Node) (string, error) {
-	var result string
-
-	path := xmlpath.MustCompile(`//*[@id="collapseobj_aboutme"]/div/ul/li[1]/dl/dd[1]`)
-
-	result, ok := path.String(root)
-	if !ok {
-		return result, errors.New("user bio xmlpath did not return a result")
-	}
-
-	return result, nil
+// WithStorage attaches db to fc so poller state (the last seen post count
+// per watched user) survives restarts. Returns fc to allow chaining from
+// NewForumClient.
+func (fc *ForumClient) WithStorage(db *storage.API) *ForumClient {
+	fc.storage = db
+	return fc
 }
 
-// getFirstTenUserVisitorMessages returns up to ten visitor messages from
-func (fc *ForumClient) getFirstTenUserVisitorMessages(root *xmlpath.Node) (result []VisitorMessage, err error) {
-	mainPath := xmlpath.MustCompile(`//*[@id="message_list"]/*`)
-	userPath := xmlpath.MustCompile(`.//div[2]/div[1]/div/a`)
-	textPath := xmlpath.MustCompile(`.//div[2]/div[2]`)
-
-	if !mainPath.Exists(root) {
-		return result, errors.New("visitor messages xmlpath did not return a result")
-	}
-
-	var ok bool
-	var user string
-	var text string
-
-	messageBlock := mainPath.Iter(root)
-
-	for messageBlock.Next() {
-		user, ok = userPath.String(messageBlock.Node())
-		if !ok {
-			continue
-		}
-		text, ok = textPath.String(messageBlock.Node())
-		if !ok {
-			continue
-		}
-
-		result = append(result, VisitorMessage{UserName: user, Message: text})
-	}
-
-	return result, nil
+// WithPollInterval overrides the base delay and jitter between poller
+// passes (see types.Config.Forum.PollInterval/PollJitter); the zero value
+// for either falls back to the package defaults. Returns fc to allow
+// chaining from NewForumClient.
+func (fc *ForumClient) WithPollInterval(interval, jitter time.Duration) *ForumClient {
+	fc.pollInterval = interval
+	fc.pollJitter = jitter
+	return fc
 }
 
-// NewPostAlert will call `fn` when the specified user posts or edits a post
-func (fc *ForumClient) NewPostAlert(id string, fn func()) {
-	ticker := time.NewTicker(time.Second * 10)
-	lastPostCount := -1
-
+// Shutdown waits for the poller goroutine (if one was started by Watch) to
+// return - it checks ctx.Done() once per tick, so Shutdown returns promptly
+// once a cancelled ctx makes it exit - or for ctx to be done, whichever
+// comes first. Callers should cancel the same ctx passed to Watch before
+// calling Shutdown, so a pass already in flight finishes (and stops writing
+// to storage) before Shutdown returns.
+func (fc *ForumClient) Shutdown(ctx context.Context) error {
+	done := make(chan struct{})
 	go func() {
-		for range ticker.C {
-			profile, err := fc.GetUserProfilePage("http://forum.sa-mp.com/member.php?u=" + id)
-			if err != nil {
-				return
-			}
-
-			if lastPostCount == -1 {
-				lastPostCount = profile.TotalPosts
-				continue
-			}
-
-			if lastPostCount < profile.TotalPosts {
-				fn()
-				lastPostCount = profile.TotalPosts
-			}
-		}
+		fc.pollerWG.Wait()
+		close(done)
 	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
@@ -0,0 +1,150 @@
+package forum
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/Southclaws/cj/storage"
+)
+
+// profileTTL bounds how long a cached UserProfile is trusted before a
+// CascadeGet treats it as a miss. UserProfile is fetched as a single unit -
+// Backend.UserProfile populates every field (including reputation) in one
+// pass - so there's no separate, cheaper way to refresh just the
+// reputation field, and no separate TTL for it.
+const profileTTL = time.Minute * 5
+
+// maxEntries bounds memoryStore's size: once full, the least recently used
+// entry is evicted to make room for a new one.
+const maxEntries = 1024
+
+// Store caches UserProfile lookups keyed by forum user ID so repeated calls
+// to GetUserProfilePage - which does three to four HTTP GETs per user - don't
+// hammer forum.sa-mp.com. The default implementation, returned by NewStore,
+// is an in-memory, size-bounded LRU cache with optional disk persistence via
+// storage.API.
+type Store interface {
+	// Get returns the cached profile for id, if present and not expired.
+	Get(id string) (UserProfile, bool)
+
+	// Set stores p against id, resetting its TTL.
+	Set(id string, p UserProfile)
+
+	// Load force-refreshes id from the network and stores the result,
+	// replacing any existing cache entry.
+	Load(ctx context.Context, id string) error
+
+	// CascadeGet returns the cached profile for id if present, otherwise
+	// fetches it from the network, stores it, then returns it.
+	CascadeGet(ctx context.Context, id string) (UserProfile, error)
+}
+
+// storeEntry is a cached profile plus the time at which it expires.
+type storeEntry struct {
+	id             string
+	profile        UserProfile
+	profileExpires time.Time
+}
+
+// memoryStore is the default Store: a map guarded by a mutex, with entries
+// optionally written to storage.API. Persisted entries are not read back, so
+// after a restart the cache starts empty. order tracks recency for LRU eviction once the cache reaches
+// maxEntries: front is most recently used, back is least.
+type memoryStore struct {
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+	fetch   func(ctx context.Context, id string) (UserProfile, error)
+	storage *storage.API
+}
+
+// NewStore creates a Store that fetches misses via fetch and, if db is
+// non-nil, persists entries to it under the "forum_profiles" bucket.
+func NewStore(fetch func(ctx context.Context, id string) (UserProfile, error), db *storage.API) Store {
+	return &memoryStore{
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+		fetch:   fetch,
+		storage: db,
+	}
+}
+
+func (s *memoryStore) Get(id string) (UserProfile, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.entries[id]
+	if !ok {
+		return UserProfile{}, false
+	}
+
+	entry := elem.Value.(storeEntry)
+	if time.Now().After(entry.profileExpires) {
+		return UserProfile{}, false
+	}
+
+	s.order.MoveToFront(elem)
+
+	return entry.profile, true
+}
+
+func (s *memoryStore) Set(id string, p UserProfile) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := storeEntry{
+		id:             id,
+		profile:        p,
+		profileExpires: time.Now().Add(profileTTL),
+	}
+
+	if elem, ok := s.entries[id]; ok {
+		elem.Value = entry
+		s.order.MoveToFront(elem)
+	} else {
+		s.entries[id] = s.order.PushFront(entry)
+	}
+
+	for len(s.entries) > maxEntries {
+		oldest := s.order.Back()
+		if oldest == nil {
+			break
+		}
+
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(storeEntry).id)
+	}
+
+	if s.storage != nil {
+		_ = s.storage.Set("forum_profiles", id, p)
+	}
+}
+
+func (s *memoryStore) Load(ctx context.Context, id string) error {
+	p, err := s.fetch(ctx, id)
+	if err != nil {
+		return errors.Wrap(err, "failed to fetch profile")
+	}
+
+	s.Set(id, p)
+
+	return nil
+}
+
+func (s *memoryStore) CascadeGet(ctx context.Context, id string) (UserProfile, error) {
+	if p, ok := s.Get(id); ok {
+		return p, nil
+	}
+
+	if err := s.Load(ctx, id); err != nil {
+		return UserProfile{}, err
+	}
+
+	p, _ := s.Get(id)
+
+	return p, nil
+}
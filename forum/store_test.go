@@ -0,0 +1,97 @@
+package forum
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore_SetGet(t *testing.T) {
+	s := NewStore(nil, nil)
+
+	_, ok := s.Get("1")
+	assert.False(t, ok)
+
+	s.Set("1", UserProfile{UserName: "kalcor"})
+
+	p, ok := s.Get("1")
+	require.True(t, ok)
+	assert.Equal(t, "kalcor", p.UserName)
+}
+
+func TestMemoryStore_Expiry(t *testing.T) {
+	ms := NewStore(nil, nil).(*memoryStore)
+	ms.Set("1", UserProfile{UserName: "kalcor"})
+
+	elem := ms.entries["1"]
+	entry := elem.Value.(storeEntry)
+	entry.profileExpires = time.Now().Add(-time.Second)
+	elem.Value = entry
+
+	_, ok := ms.Get("1")
+	assert.False(t, ok)
+}
+
+func TestMemoryStore_Load(t *testing.T) {
+	calls := 0
+	fetch := func(ctx context.Context, id string) (UserProfile, error) {
+		calls++
+		return UserProfile{UserName: "y_less"}, nil
+	}
+
+	s := NewStore(fetch, nil)
+
+	require.NoError(t, s.Load(context.Background(), "2"))
+	assert.Equal(t, 1, calls)
+
+	p, ok := s.Get("2")
+	require.True(t, ok)
+	assert.Equal(t, "y_less", p.UserName)
+}
+
+func TestMemoryStore_CascadeGet(t *testing.T) {
+	calls := 0
+	fetch := func(ctx context.Context, id string) (UserProfile, error) {
+		calls++
+		return UserProfile{UserName: "y_less"}, nil
+	}
+
+	s := NewStore(fetch, nil)
+
+	p, err := s.CascadeGet(context.Background(), "2")
+	require.NoError(t, err)
+	assert.Equal(t, "y_less", p.UserName)
+	assert.Equal(t, 1, calls)
+
+	// A second call should hit the cache, not fetch again.
+	_, err = s.CascadeGet(context.Background(), "2")
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestMemoryStore_LRUEviction(t *testing.T) {
+	ms := NewStore(nil, nil).(*memoryStore)
+
+	for i := 0; i < maxEntries; i++ {
+		ms.Set(string(rune(i)), UserProfile{TotalPosts: i})
+	}
+
+	// Touch the oldest entry so it's no longer least-recently-used.
+	_, ok := ms.Get(string(rune(0)))
+	require.True(t, ok)
+
+	// Adding one more entry should evict the new least-recently-used entry
+	// (index 1), not the one we just touched (index 0).
+	ms.Set("overflow", UserProfile{UserName: "new"})
+
+	_, ok = ms.Get(string(rune(0)))
+	assert.True(t, ok, "recently used entry should survive eviction")
+
+	_, ok = ms.Get(string(rune(1)))
+	assert.False(t, ok, "least recently used entry should be evicted")
+
+	assert.Len(t, ms.entries, maxEntries)
+}
@@ -0,0 +1,124 @@
+package vbulletin
+
+import (
+	"container/list"
+	"context"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"gopkg.in/xmlpath.v2"
+)
+
+// pageCacheEntry remembers the parsed root and validators for the last
+// successful fetch of a URL, so a subsequent request can be made
+// conditional and skip re-parsing entirely on a 304.
+type pageCacheEntry struct {
+	url          string
+	root         *xmlpath.Node
+	lastModified string
+	etag         string
+}
+
+// getHTMLRoot performs an HTTP GET against url and parses the response body
+// into an xmlpath root node. If url was previously fetched successfully,
+// the request carries If-Modified-Since / If-None-Match headers from that
+// fetch; a 304 response reuses the cached root instead of re-parsing.
+func (b *Backend) getHTMLRoot(ctx context.Context, url string) (*xmlpath.Node, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build request")
+	}
+	req = req.WithContext(ctx)
+
+	if cached, ok := b.getCachedPage(url); ok {
+		if cached.lastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.lastModified)
+		}
+		if cached.etag != "" {
+			req.Header.Set("If-None-Match", cached.etag)
+		}
+	}
+
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform request")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		cached, ok := b.getCachedPage(url)
+		if !ok {
+			return nil, errors.New("received 304 for a URL with no cached root")
+		}
+		return cached.root, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	root, err := xmlpath.ParseHTML(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse HTML")
+	}
+
+	b.cachePage(url, root, resp.Header)
+
+	return root, nil
+}
+
+// getCachedPage returns the cached entry for url, if any. b.pageCache is
+// shared between the poller goroutine and message-handling goroutines
+// (e.g. `!report forum`), so access is guarded by b.pageCacheMu. A hit
+// counts as a use for LRU purposes.
+func (b *Backend) getCachedPage(url string) (pageCacheEntry, bool) {
+	b.pageCacheMu.Lock()
+	defer b.pageCacheMu.Unlock()
+
+	elem, ok := b.pageCache[url]
+	if !ok {
+		return pageCacheEntry{}, false
+	}
+
+	b.pageOrder.MoveToFront(elem)
+
+	return elem.Value.(pageCacheEntry), true
+}
+
+// cachePage records root and the conditional-GET validators from header
+// against url, ready for the next getHTMLRoot call. Active forums churn
+// through a post URL per new post, so the cache is bounded to
+// maxCachedPages, evicting the least recently used entry once full.
+func (b *Backend) cachePage(url string, root *xmlpath.Node, header http.Header) {
+	b.pageCacheMu.Lock()
+	defer b.pageCacheMu.Unlock()
+
+	if b.pageCache == nil {
+		b.pageCache = make(map[string]*list.Element)
+		b.pageOrder = list.New()
+	}
+
+	entry := pageCacheEntry{
+		url:          url,
+		root:         root,
+		lastModified: header.Get("Last-Modified"),
+		etag:         header.Get("ETag"),
+	}
+
+	if elem, ok := b.pageCache[url]; ok {
+		elem.Value = entry
+		b.pageOrder.MoveToFront(elem)
+	} else {
+		b.pageCache[url] = b.pageOrder.PushFront(entry)
+	}
+
+	for len(b.pageCache) > maxCachedPages {
+		oldest := b.pageOrder.Back()
+		if oldest == nil {
+			break
+		}
+
+		b.pageOrder.Remove(oldest)
+		delete(b.pageCache, oldest.Value.(pageCacheEntry).url)
+	}
+}
@@ -0,0 +1,268 @@
+// Package vbulletin implements forum.Backend against SA:MP's vBulletin
+// forum by scraping its HTML pages with xmlpath. This is the original
+// scraping implementation, extracted so ForumClient can be backed by other
+// forum software too.
+package vbulletin
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Southclaws/go-cloudflare-scraper"
+	"github.com/pkg/errors"
+	"gopkg.in/xmlpath.v2"
+
+	"github.com/Southclaws/cj/forum"
+)
+
+// BaseURL is the root of the vBulletin forum this Backend scrapes.
+const BaseURL = "http://forum.sa-mp.com"
+
+// maxCachedPages bounds pageCache: once full, the least recently used page
+// is evicted to make room for a new one, the same way forum.Store bounds
+// its profile cache.
+const maxCachedPages = 1024
+
+// Backend implements forum.Backend by scraping forum.sa-mp.com's
+// vBulletin HTML pages.
+type Backend struct {
+	httpClient *http.Client
+
+	pageCacheMu sync.Mutex
+	pageCache   map[string]*list.Element
+	pageOrder   *list.List
+}
+
+// New creates a vBulletin-backed forum.Backend.
+func New() (*Backend, error) {
+	scrpr, err := scraper.NewTransport(http.DefaultTransport)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build cloudflare-bypassing transport")
+	}
+
+	return &Backend{httpClient: &http.Client{Transport: scrpr}}, nil
+}
+
+// UserProfile does a HTTP GET on the user's profile page then extracts
+// structured information from it.
+func (b *Backend) UserProfile(ctx context.Context, id string) (forum.UserProfile, error) {
+	var result forum.UserProfile
+	result.Source = "vbulletin"
+
+	root, err := b.getHTMLRoot(ctx, BaseURL+"/member.php?u="+id)
+	if err != nil {
+		return result, errors.Wrap(err, "failed to get HTML root for user page")
+	}
+
+	result.UserName, err = b.getUserName(root)
+	if err != nil {
+		return result, errors.Wrap(err, "url did not lead to a valid user page")
+	}
+
+	result.JoinDate, err = b.getJoinDate(root)
+	if err != nil {
+		result.Errors = append(result.Errors, err)
+	}
+
+	result.TotalPosts, err = b.getTotalPosts(root)
+	if err != nil {
+		result.Errors = append(result.Errors, err)
+	}
+
+	result.Reputation, err = b.getReputation(ctx, id)
+	if err != nil {
+		result.Errors = append(result.Errors, err)
+	}
+
+	result.BioText, err = b.getUserBio(root)
+	if err != nil {
+		result.Errors = append(result.Errors, err)
+	}
+
+	result.VisitorMessages, err = b.getFirstTenUserVisitorMessages(root)
+	if err != nil {
+		result.Errors = append(result.Errors, err)
+	}
+
+	return result, nil
+}
+
+// LatestPostCount fetches just enough of id's profile page to read their
+// total post count, skipping the extra reputation/bio/visitor-message GETs
+// UserProfile makes.
+func (b *Backend) LatestPostCount(ctx context.Context, id string) (int, error) {
+	root, err := b.getHTMLRoot(ctx, BaseURL+"/member.php?u="+id)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get HTML root for user page")
+	}
+
+	return b.getTotalPosts(root)
+}
+
+// Search runs a vBulletin quick search and returns the matching threads.
+func (b *Backend) Search(ctx context.Context, query string) ([]forum.SearchResult, error) {
+	root, err := b.getHTMLRoot(ctx, fmt.Sprintf("%s/search.php?do=process&query=%s", BaseURL, query))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get HTML root for search results")
+	}
+
+	titlePath := xmlpath.MustCompile(`//*[@id="threadslist"]/li/div/div[2]/a`)
+	hrefPath := xmlpath.MustCompile(`//*[@id="threadslist"]/li/div/div[2]/a/@href`)
+
+	titles := titlePath.Iter(root)
+	hrefs := hrefPath.Iter(root)
+
+	var results []forum.SearchResult
+	for titles.Next() && hrefs.Next() {
+		results = append(results, forum.SearchResult{
+			Title: strings.TrimSpace(titles.Node().String()),
+			URL:   BaseURL + "/" + hrefs.Node().String(),
+		})
+	}
+
+	return results, nil
+}
+
+// getUserName returns the user profile page owner name
+func (b *Backend) getUserName(root *xmlpath.Node) (string, error) {
+	var result string
+
+	path := xmlpath.MustCompile(`//*[@id="username_box"]/h1`)
+
+	result, ok := path.String(root)
+	if !ok {
+		return result, errors.New("user name xmlpath did not return a result")
+	}
+
+	return strings.Trim(result, "\n "), nil
+}
+
+// getJoinDate returns the user join date
+func (b *Backend) getJoinDate(root *xmlpath.Node) (string, error) {
+	var path *xmlpath.Path
+	var result string
+
+	path = xmlpath.MustCompile(`//*[@id="collapseobj_stats"]/div/*/ul/*[contains(.,'Join Date: ')]`)
+
+	result, ok := path.String(root)
+	if !ok {
+		return result, errors.New("join date xmlpath did not return a result")
+	}
+
+	return strings.TrimPrefix(result, "Join Date: "), nil
+}
+
+// getTotalPosts returns the user total posts
+func (b *Backend) getTotalPosts(root *xmlpath.Node) (int, error) {
+	path := xmlpath.MustCompile(`//*[@id="collapseobj_stats"]/div/fieldset[1]/ul/li[1]`)
+
+	posts, ok := path.String(root)
+	if !ok {
+		return 0, errors.New("total posts xmlpath did not return a result")
+	}
+
+	posts = strings.TrimPrefix(posts, "Total Posts: ")
+	posts = strings.Replace(posts, ",", "", -1)
+
+	result, err := strconv.Atoi(posts)
+	if err != nil {
+		return 0, errors.New("cannot convert posts to integer")
+	}
+
+	return result, nil
+}
+
+// getReputation returns the user's reputation, found by locating their most
+// recent post and reading the reputation field attached to it.
+func (b *Backend) getReputation(ctx context.Context, forumUserID string) (int, error) {
+	root, err := b.getHTMLRoot(ctx, fmt.Sprintf("%s/search.php?do=finduser&u=%s", BaseURL, forumUserID))
+	if err != nil {
+		return 0, errors.Wrap(err, "cannot get user's posts")
+	}
+
+	path := xmlpath.MustCompile(`//td[@class="alt1"]/div[@class="alt2"]/div/em/a/@href`)
+
+	// Get the first post from the list.
+	href, ok := path.String(root)
+	if !ok {
+		return 0, errors.New("cannot get user posts")
+	}
+
+	// If we have a valid post, search in it for user's reputation.
+	root, err = b.getHTMLRoot(ctx, fmt.Sprintf("%s/%s", BaseURL, href))
+	if err != nil {
+		return 0, errors.Wrap(err, "cannot get user's post in a topic")
+	}
+
+	path = xmlpath.MustCompile(fmt.Sprintf(
+		`//table[@id="%s"]/tbody/tr[@valign="top"]/td[@class="alt2"]/*/*[contains(text(),'Reputation: ')]`,
+		strings.Split(href, "#")[1],
+	))
+
+	fields := path.Iter(root)
+	var reputation string
+	for fields.Next() {
+		reputation = fields.Node().String()
+	}
+
+	reputation = strings.TrimPrefix(reputation, "Reputation: ")
+	reputation = strings.Replace(reputation, ",", "", -1)
+
+	result, err := strconv.Atoi(reputation)
+	if err != nil {
+		return 0, errors.Wrap(err, "cannot convert reputation to integer")
+	}
+
+	return result, nil
+}
+
+// getUserBio returns the bio text.
+func (b *Backend) getUserBio(root *xmlpath.Node) (string, error) {
+	var result string
+
+	path := xmlpath.MustCompile(`//*[@id="collapseobj_aboutme"]/div/ul/li[1]/dl/dd[1]`)
+
+	result, ok := path.String(root)
+	if !ok {
+		return result, errors.New("user bio xmlpath did not return a result")
+	}
+
+	return result, nil
+}
+
+// getFirstTenUserVisitorMessages returns up to ten visitor messages from
+func (b *Backend) getFirstTenUserVisitorMessages(root *xmlpath.Node) (result []forum.VisitorMessage, err error) {
+	mainPath := xmlpath.MustCompile(`//*[@id="message_list"]/*`)
+	userPath := xmlpath.MustCompile(`.//div[2]/div[1]/div/a`)
+	textPath := xmlpath.MustCompile(`.//div[2]/div[2]`)
+
+	if !mainPath.Exists(root) {
+		return result, errors.New("visitor messages xmlpath did not return a result")
+	}
+
+	var ok bool
+	var user string
+	var text string
+
+	messageBlock := mainPath.Iter(root)
+
+	for messageBlock.Next() {
+		user, ok = userPath.String(messageBlock.Node())
+		if !ok {
+			continue
+		}
+		text, ok = textPath.String(messageBlock.Node())
+		if !ok {
+			continue
+		}
+
+		result = append(result, forum.VisitorMessage{UserName: user, Message: text})
+	}
+
+	return result, nil
+}
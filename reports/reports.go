@@ -0,0 +1,198 @@
+// Package reports implements a moderation report pipeline: reports filed
+// via the `!report` family of commands (see bot/commands) are persisted
+// here and surfaced to staff for triage.
+package reports
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/pkg/errors"
+
+	"github.com/Southclaws/cj/forum"
+	"github.com/Southclaws/cj/storage"
+	"github.com/Southclaws/cj/types"
+)
+
+// bucket is the storage.API bucket reports are persisted under.
+const bucket = "reports"
+
+// SubjectType identifies what a Report is about.
+type SubjectType string
+
+// The kinds of thing a report can be filed against.
+const (
+	SubjectUser      SubjectType = "user"
+	SubjectMessage   SubjectType = "message"
+	SubjectForumPost SubjectType = "forum-post"
+)
+
+// Status tracks a Report's triage state.
+type Status string
+
+// The states a Report moves through.
+const (
+	StatusOpen     Status = "open"
+	StatusResolved Status = "resolved"
+)
+
+// DefaultReasons is used when types.Config.ReportReasons is empty, so
+// `!report` works out of the box without server owners having to
+// configure anything.
+var DefaultReasons = []string{"spam", "harassment", "impersonation", "other"}
+
+// Report is a single moderation report.
+type Report struct {
+	ID          string      `json:"id"`
+	ReporterID  string      `json:"reporter_id"`
+	SubjectType SubjectType `json:"subject_type"`
+	SubjectID   string      `json:"subject_id"`
+	Reason      string      `json:"reason"`
+	Status      Status      `json:"status"`
+	CreatedAt   time.Time   `json:"created_at"`
+
+	// ForumSnapshot captures the offender's forum profile at report time
+	// (SubjectForumPost only), so the record survives even if the forum
+	// page later changes.
+	ForumSnapshot *ForumSnapshot `json:"forum_snapshot,omitempty"`
+}
+
+// ForumSnapshot freezes the fields of a forum.UserProfile worth keeping
+// alongside a report.
+type ForumSnapshot struct {
+	UserName   string `json:"user_name"`
+	TotalPosts int    `json:"total_posts"`
+	BioText    string `json:"bio_text"`
+}
+
+// Extension persists reports via storage.API and DMs staff in
+// types.Config.ModChannel when a new one is filed.
+type Extension struct {
+	config  *types.Config
+	discord *discordgo.Session
+	storage *storage.API
+	forum   *forum.ForumClient
+}
+
+// Init implements extension.Extension.
+func (e *Extension) Init(ctx context.Context, config *types.Config, discord *discordgo.Session, store *storage.API, fc *forum.ForumClient) error {
+	e.config = config
+	e.discord = discord
+	e.storage = store
+	e.forum = fc
+	return nil
+}
+
+// OnMessage implements extension.Extension. Report filing happens through
+// the `!report` commands in bot/commands, not free-text messages.
+func (e *Extension) OnMessage(discordgo.Message) error {
+	return nil
+}
+
+// Shutdown implements extension.Extension; there's no in-flight work to
+// drain since every write goes straight to storage.API.
+func (e *Extension) Shutdown(context.Context) error {
+	return nil
+}
+
+// Reasons returns the configured report reasons, falling back to
+// DefaultReasons if the server owner hasn't customised the list.
+func (e *Extension) Reasons() []string {
+	if len(e.config.ReportReasons) > 0 {
+		return e.config.ReportReasons
+	}
+	return DefaultReasons
+}
+
+// IsValidReason reports whether reason is one of Reasons().
+func (e *Extension) IsValidReason(reason string) bool {
+	for _, r := range e.Reasons() {
+		if r == reason {
+			return true
+		}
+	}
+	return false
+}
+
+// File creates a new open Report, persists it, and notifies staff.
+func (e *Extension) File(reporterID string, subjectType SubjectType, subjectID, reason string, snapshot *ForumSnapshot) (Report, error) {
+	r := Report{
+		ID:            fmt.Sprintf("%s-%d", subjectType, time.Now().UnixNano()),
+		ReporterID:    reporterID,
+		SubjectType:   subjectType,
+		SubjectID:     subjectID,
+		Reason:        reason,
+		Status:        StatusOpen,
+		CreatedAt:     time.Now(),
+		ForumSnapshot: snapshot,
+	}
+
+	if err := e.storage.Set(bucket, r.ID, r); err != nil {
+		return r, errors.Wrap(err, "failed to persist report")
+	}
+
+	e.notify(r)
+
+	return r, nil
+}
+
+// List returns every report with the given status, or every report if
+// status is empty.
+func (e *Extension) List(status Status) ([]Report, error) {
+	var all []Report
+	if err := e.storage.List(bucket, &all); err != nil {
+		return nil, errors.Wrap(err, "failed to list reports")
+	}
+
+	if status == "" {
+		return all, nil
+	}
+
+	filtered := make([]Report, 0, len(all))
+	for _, r := range all {
+		if r.Status == status {
+			filtered = append(filtered, r)
+		}
+	}
+
+	return filtered, nil
+}
+
+// Resolve marks id's report as resolved.
+func (e *Extension) Resolve(id string) (Report, error) {
+	var r Report
+	if err := e.storage.Get(bucket, id, &r); err != nil {
+		return r, errors.Wrap(err, "report not found")
+	}
+
+	r.Status = StatusResolved
+
+	if err := e.storage.Set(bucket, id, r); err != nil {
+		return r, errors.Wrap(err, "failed to persist resolved report")
+	}
+
+	return r, nil
+}
+
+// notify DMs config.ModChannel with a summary of r, if ModChannel is set.
+func (e *Extension) notify(r Report) {
+	if e.config.ModChannel == "" {
+		return
+	}
+
+	msg := fmt.Sprintf(
+		"New report `%s`: <@%s> reported %s `%s` for *%s*",
+		r.ID, r.ReporterID, r.SubjectType, r.SubjectID, r.Reason,
+	)
+
+	if r.ForumSnapshot != nil {
+		msg += fmt.Sprintf(
+			"\n> %s - %d posts - %s",
+			r.ForumSnapshot.UserName, r.ForumSnapshot.TotalPosts, r.ForumSnapshot.BioText,
+		)
+	}
+
+	e.discord.ChannelMessageSend(e.config.ModChannel, msg)
+}
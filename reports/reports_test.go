@@ -0,0 +1,31 @@
+package reports
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Southclaws/cj/types"
+)
+
+// Reasons and IsValidReason only touch Extension.config, so they're the
+// only File/List/Resolve-adjacent behaviour testable without a real
+// storage.API (no fake/interface seam exists for it in this tree).
+
+func TestExtension_Reasons_Default(t *testing.T) {
+	e := &Extension{config: &types.Config{}}
+	assert.Equal(t, DefaultReasons, e.Reasons())
+}
+
+func TestExtension_Reasons_Configured(t *testing.T) {
+	custom := []string{"griefing", "cheating"}
+	e := &Extension{config: &types.Config{ReportReasons: custom}}
+	assert.Equal(t, custom, e.Reasons())
+}
+
+func TestExtension_IsValidReason(t *testing.T) {
+	e := &Extension{config: &types.Config{}}
+
+	assert.True(t, e.IsValidReason("spam"))
+	assert.False(t, e.IsValidReason("not-a-real-reason"))
+}